@@ -0,0 +1,28 @@
+// Package arm provides access to Cortex-M special registers and
+// instructions that aren't expressible in plain Go, for use by the chip
+// and runtime packages that build on top of them.
+package arm
+
+// ReadPRIMASK is a compiler intrinsic that reads the Cortex-M PRIMASK
+// special register: bit 0 set means all interrupts below fault priority are
+// masked (globally disabled).
+func ReadPRIMASK() uintptr
+
+// WritePRIMASK is a compiler intrinsic that writes the Cortex-M PRIMASK
+// special register; see ReadPRIMASK.
+func WritePRIMASK(mask uintptr)
+
+// DisableInterrupts globally disables interrupts by setting PRIMASK, and
+// returns the previous PRIMASK value so the caller can restore it with
+// EnableInterrupts.
+func DisableInterrupts() uintptr {
+	mask := ReadPRIMASK()
+	WritePRIMASK(1)
+	return mask
+}
+
+// EnableInterrupts restores the PRIMASK value returned by a previous call
+// to DisableInterrupts.
+func EnableInterrupts(mask uintptr) {
+	WritePRIMASK(mask)
+}
@@ -0,0 +1,93 @@
+package reflect
+
+import "unsafe"
+
+// SelectDir describes the communication direction of a SelectCase.
+type SelectDir int
+
+const (
+	_             SelectDir = iota
+	SelectSend              // case Chan <- Send
+	SelectRecv              // case <-Chan:
+	SelectDefault           // default
+)
+
+// SelectCase describes a single case in a select operation built with
+// Select. The kind of case depends on Dir, the communication direction:
+//
+//	Dir        Chan  Send
+//	SelectSend chan T value T
+//	SelectRecv chan T <zero Value>
+//	SelectDefault <zero Value> <zero Value>
+type SelectCase struct {
+	Dir  SelectDir
+	Chan Value
+	Send Value
+}
+
+// runtimeSelectCase must be kept in sync with runtime.runtimeSelectCase. recv
+// is only used for SelectRecv: it points at a buffer, sized for that case's
+// own channel element type, that the runtime fills in if this case is the
+// one chosen. Each SelectRecv case gets its own buffer rather than sharing
+// one sized for the largest element, since cases may receive different
+// types and only the chosen case's buffer is ever written.
+type runtimeSelectCase struct {
+	dir  int
+	ch   unsafe.Pointer
+	send unsafe.Pointer
+	recv unsafe.Pointer
+}
+
+// Select executes a select operation described by cases. Like the select
+// statement, it blocks until at least one of the cases can proceed, makes a
+// uniform pseudo-random choice if more than one is ready, and returns the
+// index of the chosen case. If the case was a receive, recv is the value
+// received and recvOK is true if the value corresponds to a send rather
+// than a zero value received because the channel is closed.
+//
+// Unlike the select statement, Select does not allow the channel directions
+// to be implicit. Each SelectCase must declare the direction it intends.
+func Select(cases []SelectCase) (chosen int, recv Value, recvOK bool) {
+	runtimeCases := make([]runtimeSelectCase, len(cases))
+	for i, c := range cases {
+		switch c.Dir {
+		case SelectDefault:
+			runtimeCases[i] = runtimeSelectCase{dir: int(SelectDefault)}
+		case SelectSend:
+			runtimeCases[i] = runtimeSelectCase{
+				dir:  int(SelectSend),
+				ch:   c.Chan.pointer(),
+				send: c.Send.pointer(),
+			}
+		case SelectRecv:
+			elem := c.Chan.typecode.elem()
+			var buf unsafe.Pointer
+			if elem.size > 0 {
+				buf = unsafe.Pointer(&make([]byte, elem.size)[0])
+			}
+			runtimeCases[i] = runtimeSelectCase{
+				dir:  int(SelectRecv),
+				ch:   c.Chan.pointer(),
+				recv: buf,
+			}
+		default:
+			panic("reflect.Select: invalid SelectCase")
+		}
+	}
+
+	chosen, recvOK = reflectSelect(runtimeCases)
+	if cases[chosen].Dir == SelectRecv {
+		recv = Value{
+			typecode: cases[chosen].Chan.typecode.elem(),
+			value:    runtimeCases[chosen].recv,
+		}
+	}
+	return
+}
+
+// reflectSelect is implemented in package runtime (see reflectSelect in
+// chan_reflect.go), where it is backed by the same fair pollorder/lockorder
+// scheduling as a compiler-lowered select statement.
+//
+//go:linkname reflectSelect runtime.reflectSelect
+func reflectSelect(cases []runtimeSelectCase) (chosen int, recvOK bool)
@@ -0,0 +1,328 @@
+package runtime
+
+import (
+	"internal/task"
+	"runtime/interrupt"
+	"unsafe"
+)
+
+// channel is the internal implementation of a Go channel value (the hidden
+// struct behind the chan T type). A channel is always allocated through
+// chanMake and accessed through a pointer: the "chan T" value used in Go code
+// is really a *channel under the hood.
+//
+// For a buffered channel whose element type holds no pointers, buf points
+// just past the end of this struct: chanMake allocates the channel header
+// and its dataqsiz*elementSize bytes of ring storage as one contiguous
+// mallocgc'd block, the same layout upstream Go's hchan uses. That saves an
+// allocation and a pointer indirection per channel, which matters on MCU
+// targets where every heap allocation is comparatively expensive. If the
+// element type contains pointers, buf instead points at a second, separately
+// scanned allocation, so the GC's pointer bitmap for the channel header
+// itself doesn't have to account for a variable-length tail of maybe-pointer
+// data.
+//
+// qcount, rather than a reserved "always empty" slot, disambiguates an empty
+// ring buffer from a full one; len(ch) and cap(ch) are plain reads of qcount
+// and dataqsiz.
+//
+// Unbuffered channels have dataqsiz == 0 and never hold a value in buf: every
+// send must be matched with a concurrently waiting receiver (or vice versa).
+type channel struct {
+	elementSize uintptr
+	dataqsiz    uintptr
+	closed      bool
+
+	buf    unsafe.Pointer
+	sendx  uintptr
+	recvx  uintptr
+	qcount uintptr
+
+	recvq waitq
+	sendq waitq
+}
+
+// sudog ("pseudo-g") represents a goroutine that is blocked on a channel
+// operation. It is pushed onto a channel's recvq or sendq while the
+// goroutine is parked, and is removed again once the operation can proceed.
+type sudog struct {
+	t    *task.Task
+	elem unsafe.Pointer // pointer to the value being sent/received
+	next *sudog
+}
+
+// waitq is a singly linked FIFO queue of goroutines parked on one side
+// (send or receive) of a channel.
+type waitq struct {
+	first *sudog
+	last  *sudog
+}
+
+func (q *waitq) enqueue(sg *sudog) {
+	sg.next = nil
+	if q.last == nil {
+		q.first = sg
+	} else {
+		q.last.next = sg
+	}
+	q.last = sg
+}
+
+func (q *waitq) dequeue() *sudog {
+	sg := q.first
+	if sg == nil {
+		return nil
+	}
+	q.first = sg.next
+	if q.first == nil {
+		q.last = nil
+	}
+	sg.next = nil
+	return sg
+}
+
+// chanHeaderSize is the size of a channel header, i.e. everything in struct
+// channel before the inline ring buffer that chanMake appends to it.
+var chanHeaderSize = unsafe.Sizeof(channel{})
+
+// chanMake creates a new channel with room for dataqsiz elements of the given
+// size. It is called from IR generated for the make(chan T[, n]) builtin;
+// elementNoPointers comes from the element type's layout, computed by the
+// compiler, and says whether the ring buffer needs to be scanned by the GC.
+func chanMake(elementSize uintptr, dataqsiz uintptr, elementNoPointers bool) *channel {
+	if dataqsiz == 0 {
+		return &channel{elementSize: elementSize}
+	}
+
+	if elementNoPointers {
+		// Single allocation: the header and the ring buffer live in one
+		// mallocgc'd block, with buf pointing just past the header.
+		block := alloc(chanHeaderSize+elementSize*dataqsiz, nil)
+		ch := (*channel)(block)
+		ch.elementSize = elementSize
+		ch.dataqsiz = dataqsiz
+		ch.buf = unsafe.Add(block, chanHeaderSize)
+		return ch
+	}
+
+	// The element type holds pointers: give the ring buffer its own
+	// precisely-scanned allocation instead of folding it into the header.
+	return &channel{
+		elementSize: elementSize,
+		dataqsiz:    dataqsiz,
+		buf:         alloc(elementSize*dataqsiz, nil),
+	}
+}
+
+func chanLen(ch *channel) int {
+	if ch == nil {
+		return 0
+	}
+	return int(ch.qcount)
+}
+
+func chanCap(ch *channel) int {
+	if ch == nil {
+		return 0
+	}
+	return int(ch.dataqsiz)
+}
+
+// bufSlot returns a pointer to the i'th slot of the channel's ring buffer.
+func (ch *channel) bufSlot(i uintptr) unsafe.Pointer {
+	return unsafe.Add(ch.buf, i*ch.elementSize)
+}
+
+// chanSend sends a value on the channel, blocking the current goroutine
+// until the value has been delivered (directly to a waiting receiver, or
+// into the ring buffer).
+func chanSend(ch *channel, value unsafe.Pointer) {
+	if ch == nil {
+		// Sending on a nil channel blocks forever.
+		deadlock()
+	}
+
+	for {
+		state := interrupt.Disable()
+
+		if ch.closed {
+			interrupt.Restore(state)
+			runtimePanic("send on closed channel")
+		}
+
+		if sg := ch.recvq.dequeue(); sg != nil {
+			memcpy(sg.elem, value, ch.elementSize)
+			interrupt.Restore(state)
+			sg.t.Resume()
+			return
+		}
+
+		if ch.qcount < ch.dataqsiz {
+			memcpy(ch.bufSlot(ch.sendx), value, ch.elementSize)
+			ch.sendx = (ch.sendx + 1) % ch.dataqsiz
+			ch.qcount++
+			interrupt.Restore(state)
+			return
+		}
+
+		sg := &sudog{t: task.Current(), elem: value}
+		ch.sendq.enqueue(sg)
+		interrupt.Restore(state)
+
+		sg.t.Pause()
+		// Woken up: either chanRecv consumed the value directly (sg.elem is
+		// cleared to nil) or the channel was closed while we were parked
+		// (sg.elem is set to the sentinel, and we must panic).
+		if sg.elem == closedChanSentinel {
+			runtimePanic("send on closed channel")
+		}
+		return
+	}
+}
+
+// chanRecv receives a value from the channel into value, blocking the
+// current goroutine until a value is available. It reports whether the
+// value came from an open channel (ok) as the second return.
+func chanRecv(ch *channel, value unsafe.Pointer) (ok bool) {
+	if ch == nil {
+		deadlock()
+	}
+
+	for {
+		state := interrupt.Disable()
+
+		if sg := ch.sendq.dequeue(); sg != nil {
+			memcpy(value, sg.elem, ch.elementSize)
+			sg.elem = nil // signal the sender that its value was delivered
+			interrupt.Restore(state)
+			sg.t.Resume()
+			return true
+		}
+
+		if ch.qcount > 0 {
+			memcpy(value, ch.bufSlot(ch.recvx), ch.elementSize)
+			ch.recvx = (ch.recvx + 1) % ch.dataqsiz
+			ch.qcount--
+			interrupt.Restore(state)
+			return true
+		}
+
+		if ch.closed {
+			memzero(value, ch.elementSize)
+			interrupt.Restore(state)
+			return false
+		}
+
+		sg := &sudog{t: task.Current(), elem: value}
+		ch.recvq.enqueue(sg)
+		interrupt.Restore(state)
+
+		sg.t.Pause()
+	}
+}
+
+// chanClose closes a channel, waking up any parked receivers (with the zero
+// value) and marking any parked senders to panic once resumed.
+func chanClose(ch *channel) {
+	if ch == nil {
+		runtimePanic("close of nil channel")
+	}
+
+	state := interrupt.Disable()
+	if ch.closed {
+		interrupt.Restore(state)
+		runtimePanic("close of closed channel")
+	}
+	ch.closed = true
+	recvq := ch.recvq
+	sendq := ch.sendq
+	ch.recvq = waitq{}
+	ch.sendq = waitq{}
+	interrupt.Restore(state)
+
+	for sg := recvq.dequeue(); sg != nil; sg = recvq.dequeue() {
+		memzero(sg.elem, ch.elementSize)
+		sg.t.Resume()
+	}
+	for sg := sendq.dequeue(); sg != nil; sg = sendq.dequeue() {
+		sg.elem = closedChanSentinel
+		sg.t.Resume()
+	}
+}
+
+// closedChanSentinel is a non-nil marker stored in a parked sender's sudog
+// to tell it, once resumed, that the channel was closed out from under it
+// rather than having its value consumed normally.
+var closedChanSentinel unsafe.Pointer = unsafe.Pointer(&struct{}{})
+
+// chanSendFromISR is the runtime side of interrupt.Channel.SendFromISR. It
+// runs in interrupt context and does not call interrupt.Disable. The
+// goroutine-context code that touches a channel's buffer indices, qcount and
+// wait queues (chanSend, chanRecv, chanClose and chanSelect) does so only
+// between interrupt.Disable and interrupt.Restore, so this function never
+// interleaves with a half-finished update there. For that reason sendx and
+// qcount are advanced with ordinary, non-atomic stores, and recvq is popped
+// directly.
+//
+// A parked receiver is woken through queueISRResume rather than by calling
+// Resume directly: an interrupt can land at any instruction boundary in the
+// goroutine it preempted, including mid-mutation of the scheduler's run
+// queue inside some other task's Pause/Resume, so touching that queue from
+// interrupt context is not safe. queueISRResume only ever records the task;
+// drainISRResumes does the actual Resume once we're back in thread context.
+func chanSendFromISR(ch unsafe.Pointer, value unsafe.Pointer) bool {
+	c := (*channel)(ch)
+	if c.qcount >= c.dataqsiz {
+		return false
+	}
+	memcpy(c.bufSlot(c.sendx), value, c.elementSize)
+	c.sendx = (c.sendx + 1) % c.dataqsiz
+	c.qcount++ // publishes the slot to chanRecv
+
+	if sg := c.recvq.dequeue(); sg != nil {
+		queueISRResume(sg.t)
+	}
+	return true
+}
+
+// isrResumeQueue holds tasks that an interrupt handler asked to be resumed,
+// but which must not actually be resumed until execution is back in thread
+// context (see chanSendFromISR). It is sized for a small handful of pending
+// wakes; in practice a given interrupt priority level can only nest so deep
+// before drainISRResumes runs again on the way out.
+var (
+	isrResumeQueue               [8]*task.Task
+	isrResumeHead, isrResumeTail uint8
+)
+
+// queueISRResume records t as needing to be resumed once drainISRResumes
+// next runs. It must only be called from interrupt context, where hardware
+// has already suspended the thread we'd otherwise race with, so no separate
+// lock is needed here.
+//
+// If the queue is full, the oldest pending resume is dropped in favor of
+// the new one rather than silently discarding t: chanSendFromISR already
+// published the value before calling this, so the receiver has a value
+// waiting for it regardless of which wake actually reaches it.
+func queueISRResume(t *task.Task) {
+	next := (isrResumeTail + 1) % uint8(len(isrResumeQueue))
+	if next == isrResumeHead {
+		isrResumeHead = (isrResumeHead + 1) % uint8(len(isrResumeQueue))
+	}
+	isrResumeQueue[isrResumeTail] = t
+	isrResumeTail = next
+}
+
+// drainISRResumes resumes every task queued by queueISRResume. The compiler
+// arranges to call it from thread context on return from every interrupt
+// handler registered through interrupt.New, after the handler itself has
+// run but before interrupts are re-enabled, so it never races a fresh call
+// to queueISRResume.
+func drainISRResumes() {
+	for isrResumeHead != isrResumeTail {
+		t := isrResumeQueue[isrResumeHead]
+		isrResumeQueue[isrResumeHead] = nil
+		isrResumeHead = (isrResumeHead + 1) % uint8(len(isrResumeQueue))
+		t.Resume()
+	}
+}
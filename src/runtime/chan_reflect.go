@@ -0,0 +1,54 @@
+package runtime
+
+import "unsafe"
+
+// runtimeSelectDir mirrors reflect.SelectDir: it says what kind of
+// operation a runtimeSelectCase performs, using the same numbering reflect
+// already exports so the two packages don't need to translate between them.
+type runtimeSelectDir int
+
+const (
+	_ runtimeSelectDir = iota
+	runtimeSelectSend
+	runtimeSelectRecv
+	runtimeSelectDefault
+)
+
+// runtimeSelectCase is the runtime's view of a reflect.SelectCase: a channel
+// (as an unsafe.Pointer to a *channel, or nil for SelectDefault), the value
+// to send for SelectSend, a per-case receive buffer for SelectRecv, and the
+// direction. reflect.Select builds a slice of these from the SelectCase
+// values it was given and passes it to reflectSelect.
+//
+// recv is deliberately per-case rather than one buffer shared across every
+// SelectRecv case: different cases may receive different (and differently
+// sized) element types, and only the chosen case's buffer is ever written,
+// so a shared buffer sized for the largest case would mislabel a smaller
+// type's bytes with the wrong type once reflect.Select wraps them.
+type runtimeSelectCase struct {
+	dir  runtimeSelectDir
+	ch   unsafe.Pointer
+	send unsafe.Pointer
+	recv unsafe.Pointer
+}
+
+// reflectSelect runs a select statement built at runtime, e.g. through
+// reflect.Select. It reuses the same fair scheduling core as compile-time
+// select (chanSelect's pollorder/lockorder), so a reflect-driven select is
+// scheduled exactly as fairly as one the compiler lowered directly.
+func reflectSelect(cases []runtimeSelectCase) (chosen int, recvOK bool) {
+	compiled := make([]selectCase, len(cases))
+	for i, c := range cases {
+		switch c.dir {
+		case runtimeSelectSend:
+			compiled[i] = selectCase{ch: (*channel)(c.ch), value: c.send, kind: selectSend}
+		case runtimeSelectRecv:
+			compiled[i] = selectCase{ch: (*channel)(c.ch), value: c.recv, kind: selectRecv}
+		case runtimeSelectDefault:
+			compiled[i] = selectCase{kind: selectDefault}
+		default:
+			runtimePanic("reflect.Select: invalid SelectDir")
+		}
+	}
+	return chanSelect(compiled)
+}
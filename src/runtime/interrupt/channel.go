@@ -0,0 +1,54 @@
+package interrupt
+
+import "unsafe"
+
+// Channel bridges an interrupt handler to a regular Go channel. An ISR that
+// cannot safely use `ch <- v` (an ordinary send may need the scheduler)
+// instead calls SendFromISR on a Channel wrapping ch, and the value shows up
+// on the other end via the usual `<-ch` or `for range ch`.
+//
+// SendFromISR writes straight into ch's own ring buffer, the same
+// circular-buffer discipline the runtime uses for ordinary buffered channels
+// (qcount/dataqsiz/sendx/recvx). It advances sendx and qcount with ordinary,
+// non-atomic stores and does not call Disable. The runtime touches a
+// channel's buffer and wait queues from goroutine context only with
+// interrupts disabled, so the handler never interleaves with a half-finished
+// update there. Once the value is published, the runtime hook below pops one
+// parked receiver off recvq and queues it to be resumed once we're back in
+// thread context, rather than resuming it from inside the interrupt (see
+// New).
+//
+// ch must be a buffered channel with enough capacity that SendFromISR is
+// unlikely to ever observe it full; a full channel causes SendFromISR to
+// drop the value and return false rather than block.
+//
+// Do not use the zero value of a Channel. Call NewChan to obtain one.
+type Channel[T any] struct {
+	ch unsafe.Pointer // the *runtime.channel backing ch
+}
+
+// NewChan wraps ch so it can be sent to from interrupt context with
+// SendFromISR. ch must already have been created with make(chan T, n) for
+// some n > 0.
+func NewChan[T any](ch chan T) *Channel[T] {
+	return &Channel[T]{ch: *(*unsafe.Pointer)(unsafe.Pointer(&ch))}
+}
+
+// SendFromISR pushes v onto the wrapped channel's ring buffer and wakes a
+// goroutine waiting to receive from it. It must only be called from within
+// an interrupt handler registered through New. It reports whether there was
+// room in the buffer. Unlike a send on a full buffered channel it never
+// blocks: a full buffer drops v and returns false instead.
+//
+//go:nosplit
+func (c *Channel[T]) SendFromISR(v T) bool {
+	return chanSendFromISR(c.ch, unsafe.Pointer(&v))
+}
+
+// chanSendFromISR is implemented in package runtime (see chanSendFromISR in
+// chan.go). It publishes *value into the channel's ring buffer with plain
+// sendx/qcount updates and queues one parked receiver to be resumed once
+// we're back in thread context, all without disabling interrupts itself.
+//
+//go:linkname chanSendFromISR runtime.chanSendFromISR
+func chanSendFromISR(ch unsafe.Pointer, value unsafe.Pointer) bool
@@ -7,6 +7,12 @@ package interrupt
 //
 // Do not use the zero value of an Interrupt object. Instead, call New to obtain
 // an interrupt handle.
+//
+// Enable, Disable, SetPriority, Priority, Trigger, ClearPending and
+// IsPending give control over this individual interrupt in the interrupt
+// controller (for example, the NVIC on Cortex-M). Implemented per chip;
+// see interrupt_cortexm.go for the Cortex-M backend and interrupt_none.go
+// for the portable fallback used where no backend exists yet.
 type Interrupt struct {
 	// Make this number unexported so it cannot be set directly. This provides
 	// some encapsulation.
@@ -17,9 +23,36 @@ type Interrupt struct {
 // it only once, and must pass constant parameters to it. That means that the
 // interrupt ID must be a Go constant and that the handler must be a simple
 // function: closures are not supported.
+//
+// The trampoline the compiler generates around handler also drains any
+// scheduler wakes the runtime queued up while handler ran (for example from
+// a channel fed by interrupt.Channel.SendFromISR), so they happen once
+// execution is back in thread context rather than from inside the handler.
 func New(id int, handler func(Interrupt)) Interrupt
 
 type handle struct {
 	handler func(Interrupt)
 	Interrupt
 }
+
+// State is the saved global interrupt-enable state returned by Disable, to
+// be passed back to Restore. Treat it as opaque; its representation (for
+// example Cortex-M's PRIMASK) is chip-specific.
+//
+// Disable and Restore disable all interrupts globally and restore the
+// previous state, so that library code can build a short critical section:
+//
+//	state := interrupt.Disable()
+//	// critical section: no interrupt handler can run here
+//	interrupt.Restore(state)
+//
+// Critical sections built this way must be short: while interrupts are
+// disabled, nothing else on the chip can run, including the scheduler.
+// Disable/Restore pairs nest correctly, since Restore puts back whatever
+// state Disable observed rather than unconditionally re-enabling interrupts.
+//
+// It is used by the runtime to build short critical sections around state
+// that can also be touched from interrupt context, such as a channel that
+// an ISR sends into via interrupt.Channel. Implemented per chip; see
+// interrupt_cortexm.go and interrupt_none.go.
+type State uintptr
@@ -0,0 +1,69 @@
+//go:build cortexm
+
+package interrupt
+
+import (
+	"device/arm"
+	"unsafe"
+)
+
+// NVIC register base addresses. These are fixed across every Cortex-M
+// vendor (they're part of the ARMv6-M/v7-M architecture, not the chip), so
+// unlike most peripherals they don't need a per-chip register definition
+// file to use.
+const (
+	nvicISER = 0xE000E100 // Interrupt Set-Enable Registers
+	nvicICER = 0xE000E180 // Interrupt Clear-Enable Registers
+	nvicISPR = 0xE000E200 // Interrupt Set-Pending Registers
+	nvicICPR = 0xE000E280 // Interrupt Clear-Pending Registers
+	nvicIPR  = 0xE000E400 // Interrupt Priority Registers, one byte per interrupt
+	nvicSTIR = 0xE000EF00 // Software Trigger Interrupt Register
+)
+
+// nvicWord returns the ISER/ICER/ISPR/ICPR register word that holds num's
+// bit: each of those registers packs 32 interrupts' worth of one-bit flags
+// per 32-bit word, indexed by num/32, with the bit itself at num%32.
+func nvicWord(base uintptr, num int) *uint32 {
+	return (*uint32)(unsafe.Pointer(base + uintptr(num/32)*4))
+}
+
+func (i Interrupt) Enable() {
+	*nvicWord(nvicISER, i.num) = 1 << uint(i.num%32)
+}
+
+func (i Interrupt) Disable() {
+	*nvicWord(nvicICER, i.num) = 1 << uint(i.num%32)
+}
+
+func (i Interrupt) SetPriority(priority uint8) {
+	reg := (*uint8)(unsafe.Pointer(uintptr(nvicIPR) + uintptr(i.num)))
+	*reg = priority
+}
+
+func (i Interrupt) Priority() uint8 {
+	reg := (*uint8)(unsafe.Pointer(uintptr(nvicIPR) + uintptr(i.num)))
+	return *reg
+}
+
+func (i Interrupt) Trigger() {
+	*(*uint32)(unsafe.Pointer(uintptr(nvicSTIR))) = uint32(i.num)
+}
+
+func (i Interrupt) ClearPending() {
+	*nvicWord(nvicICPR, i.num) = 1 << uint(i.num%32)
+}
+
+func (i Interrupt) IsPending() bool {
+	return *nvicWord(nvicISPR, i.num)&(1<<uint(i.num%32)) != 0
+}
+
+// Disable disables all interrupts globally via PRIMASK; see the doc comment
+// on State in interrupt.go.
+func Disable() State {
+	return State(arm.DisableInterrupts())
+}
+
+// Restore restores the PRIMASK value saved by Disable.
+func Restore(state State) {
+	arm.EnableInterrupts(uintptr(state))
+}
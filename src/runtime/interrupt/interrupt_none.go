@@ -0,0 +1,31 @@
+//go:build !cortexm
+
+package interrupt
+
+// This file backs the Interrupt methods and the package-level Disable/
+// Restore for targets with no interrupt controller backend yet (see
+// interrupt_cortexm.go for the one real backend). It's a portable no-op:
+// Disable/Restore just track nesting depth rather than touching real
+// hardware state, so code written against this package still builds and
+// runs (with interrupts effectively always "enabled" and never delivered,
+// since such targets don't raise them in the first place) instead of
+// failing to link.
+
+var disableDepth uint32
+
+func (i Interrupt) Enable()             {}
+func (i Interrupt) Disable()            {}
+func (i Interrupt) SetPriority(_ uint8) {}
+func (i Interrupt) Priority() uint8     { return 0 }
+func (i Interrupt) Trigger()            {}
+func (i Interrupt) ClearPending()       {}
+func (i Interrupt) IsPending() bool     { return false }
+
+func Disable() State {
+	disableDepth++
+	return State(disableDepth)
+}
+
+func Restore(state State) {
+	disableDepth = uint32(state) - 1
+}
@@ -0,0 +1,256 @@
+package runtime
+
+import (
+	"internal/task"
+	"runtime/interrupt"
+	"unsafe"
+)
+
+// selectCaseKind identifies what kind of operation a selectCase performs.
+// It matches the values the compiler emits for a select statement lowered
+// through chanSelect.
+type selectCaseKind uint8
+
+const (
+	selectRecv selectCaseKind = iota
+	selectSend
+	selectDefault
+)
+
+// selectCase is one arm of a select statement, as built by the compiler (or,
+// for reflect.Select, by package reflect) before calling chanSelect.
+type selectCase struct {
+	ch    *channel
+	value unsafe.Pointer // send: value to send. recv: where to store the received value.
+	kind  selectCaseKind
+}
+
+// chanSelect runs a select statement over cases, following the same
+// algorithm as upstream Go's selectgo: a shuffled pollorder decides which
+// ready case wins when several are ready at once, and a pollorder-independent
+// lockorder (cases sorted by channel address, deduplicated) fixes the order
+// channels are locked in so that two selects racing over the same channels
+// can never deadlock against each other.
+//
+// It returns the index of the chosen case and, for a receive, whether the
+// value came from an open channel.
+func chanSelect(cases []selectCase) (chosen int, recvOK bool) {
+	if len(cases) == 0 {
+		deadlock()
+	}
+
+	pollorder := makePollorder(len(cases))
+	waitOrder := selectableCases(cases)
+	lockorder := makeLockorder(cases, waitOrder)
+
+	lockState := lockAll(cases, lockorder)
+
+	// Pass 1: look for a case that can proceed immediately, without parking.
+	for _, i := range pollorder {
+		c := &cases[i]
+		switch c.kind {
+		case selectDefault:
+			continue
+		case selectRecv:
+			if c.ch == nil {
+				// A nil channel is never ready; sending or receiving on it
+				// blocks forever, so this case just never wins pass 1.
+				continue
+			}
+			if sg := c.ch.sendq.dequeue(); sg != nil {
+				memcpy(c.value, sg.elem, c.ch.elementSize)
+				sg.elem = nil
+				unlockAll(cases, lockorder, lockState)
+				sg.t.Resume()
+				return i, true
+			}
+			if c.ch.qcount > 0 {
+				memcpy(c.value, c.ch.bufSlot(c.ch.recvx), c.ch.elementSize)
+				c.ch.recvx = (c.ch.recvx + 1) % c.ch.dataqsiz
+				c.ch.qcount--
+				unlockAll(cases, lockorder, lockState)
+				return i, true
+			}
+			if c.ch.closed {
+				memzero(c.value, c.ch.elementSize)
+				unlockAll(cases, lockorder, lockState)
+				return i, false
+			}
+		case selectSend:
+			if c.ch == nil {
+				continue
+			}
+			if c.ch.closed {
+				unlockAll(cases, lockorder, lockState)
+				runtimePanic("send on closed channel")
+			}
+			if sg := c.ch.recvq.dequeue(); sg != nil {
+				memcpy(sg.elem, c.value, c.ch.elementSize)
+				unlockAll(cases, lockorder, lockState)
+				sg.t.Resume()
+				return i, true
+			}
+			if c.ch.qcount < c.ch.dataqsiz {
+				memcpy(c.ch.bufSlot(c.ch.sendx), c.value, c.ch.elementSize)
+				c.ch.sendx = (c.ch.sendx + 1) % c.ch.dataqsiz
+				c.ch.qcount++
+				unlockAll(cases, lockorder, lockState)
+				return i, true
+			}
+		}
+	}
+
+	// Nothing ready. If there's a default case, take it.
+	for _, i := range pollorder {
+		if cases[i].kind == selectDefault {
+			unlockAll(cases, lockorder, lockState)
+			return i, false
+		}
+	}
+
+	// Pass 2: park on every case and wait to be woken by whichever fires
+	// first. This must cover every waitable case, not just one per distinct
+	// channel: two cases sharing a channel (e.g. two receives on the same
+	// ch, or a send and a receive on it) are still two independent sudogs,
+	// each of which can be the one that wakes us.
+	self := task.Current()
+	sudogs := make([]*sudog, len(cases))
+	for _, i := range waitOrder {
+		c := &cases[i]
+		sg := &sudog{t: self, elem: c.value}
+		sudogs[i] = sg
+		switch c.kind {
+		case selectRecv:
+			c.ch.recvq.enqueue(sg)
+		case selectSend:
+			c.ch.sendq.enqueue(sg)
+		}
+	}
+	unlockAll(cases, lockorder, lockState)
+	self.Pause()
+	lockState = lockAll(cases, lockorder)
+
+	// Exactly one sudog was serviced (its elem was consumed/delivered, or
+	// cleared by a close); find it and dequeue the rest.
+	chosen = -1
+	for _, i := range waitOrder {
+		c := &cases[i]
+		sg := sudogs[i]
+		switch c.kind {
+		case selectRecv:
+			if !dequeueSudog(&c.ch.recvq, sg) {
+				if sg.elem == nil {
+					recvOK = true
+				}
+				chosen = i
+			}
+		case selectSend:
+			if !dequeueSudog(&c.ch.sendq, sg) {
+				if sg.elem == closedChanSentinel {
+					unlockAll(cases, lockorder, lockState)
+					runtimePanic("send on closed channel")
+				}
+				chosen = i
+			}
+		}
+	}
+	unlockAll(cases, lockorder, lockState)
+	return chosen, recvOK
+}
+
+// makePollorder returns a Fisher-Yates shuffle of 0..n-1, used to pick which
+// ready case wins when several are ready. Shuffling (rather than always
+// preferring the statically first case) is what makes concurrent senders or
+// receivers on a select make fair progress instead of starving.
+func makePollorder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := int(fastrand() % uint32(i+1))
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// selectableCases returns the indices of every case that can actually wait
+// on a channel, i.e. every non-default case with a non-nil channel. Unlike
+// lockorder, this keeps every such case even if several share a channel:
+// each one still needs its own sudog enqueued and resolved in pass 2.
+func selectableCases(cases []selectCase) []int {
+	order := make([]int, 0, len(cases))
+	for i, c := range cases {
+		// A nil channel, like a default case, never contributes a ready
+		// operation (it blocks forever), so it needs neither a lock nor a
+		// spot in recvq/sendq.
+		if c.kind != selectDefault && c.ch != nil {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// makeLockorder sorts waitable (a la selectableCases) by channel address and
+// deduplicates them, so that locking in this order is consistent across
+// every select that might share one of these channels. The dedup here is
+// only about not locking the same channel twice; it must not be reused as
+// the set of cases to enqueue/resolve in pass 2 (see selectableCases).
+func makeLockorder(cases []selectCase, waitable []int) []int {
+	order := append([]int(nil), waitable...)
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && lessChan(cases[order[j]].ch, cases[order[j-1]].ch); j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	deduped := order[:0]
+	for i, idx := range order {
+		if i == 0 || cases[idx].ch != cases[order[i-1]].ch {
+			deduped = append(deduped, idx)
+		}
+	}
+	return deduped
+}
+
+func lessChan(a, b *channel) bool {
+	return uintptr(unsafe.Pointer(a)) < uintptr(unsafe.Pointer(b))
+}
+
+// lockAll and unlockAll acquire/release the channels' locks in lockorder.
+// TinyGo's scheduler is cooperative, so the only thing that can run between
+// chanSelect's steps and race with it is an interrupt handler (for example
+// one using interrupt.Channel); there's no second CPU-bound goroutine to
+// take a per-channel lock against. So "locking" here just disables
+// interrupts for the duration, via the same primitive package interrupt
+// exposes to other library code for short critical sections. lockorder is
+// still computed and threaded through so a real per-channel lock can drop
+// in later without touching callers.
+func lockAll(cases []selectCase, lockorder []int) interrupt.State {
+	return interrupt.Disable()
+}
+
+func unlockAll(cases []selectCase, lockorder []int, state interrupt.State) {
+	interrupt.Restore(state)
+}
+
+// dequeueSudog removes sg from q, reporting whether it was still present
+// (false means something already serviced it and removed it for us).
+func dequeueSudog(q *waitq, sg *sudog) bool {
+	if q.first == sg {
+		q.first = sg.next
+		if q.first == nil {
+			q.last = nil
+		}
+		return true
+	}
+	for p := q.first; p != nil; p = p.next {
+		if p.next == sg {
+			p.next = sg.next
+			if q.last == sg {
+				q.last = p
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -230,6 +230,115 @@ func main() {
 	}
 	wg.wait()
 	println("blocking select sum:", sum)
+
+	// Regression: close a channel while a goroutine is still ranging over
+	// it and another goroutine is still trying to send to it.
+	ch = make(chan int, 1)
+	wg.add(2)
+	go func() {
+		defer wg.done()
+		for i := 0; i < 5; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	go sendUntilClosed(ch)
+	sum = 0
+	for v := range ch {
+		sum += v
+	}
+	println("close vs range sum:", sum)
+	wg.wait()
+
+	// Regression: a goroutine blocked on a select-send races a close of
+	// that channel.
+	ch = make(chan int)
+	wg.add(1)
+	go selectSendUntilClosed(ch)
+	time.Sleep(time.Millisecond)
+	close(ch)
+	wg.wait()
+
+	// Regression: several senders parked on a full buffered channel, plus a
+	// receiver that only shows up after close, all see the close correctly.
+	ch = make(chan int, 1)
+	ch <- 0 // fill the buffer so further sends park in sendq
+	wg.add(3)
+	go sendUntilClosed(ch)
+	go sendUntilClosed(ch)
+	go sendUntilClosed(ch)
+	time.Sleep(time.Millisecond) // let the senders run and park in sendq
+	close(ch)
+	wg.wait()
+	n, ok = <-ch
+	println("recv from closed buffered channel after waiters:", n, ok)
+
+	// Regression: a select with two cases on the *same* channel must still
+	// park (and be wakeable) on both of them, not just one.
+	ch = make(chan int)
+	wg.add(1)
+	go func(ch chan int) {
+		defer wg.done()
+		runtime.Gosched()
+		ch <- 77
+	}(ch)
+	select {
+	case v := <-ch:
+		println("same-channel select case 0:", v)
+	case v := <-ch:
+		println("same-channel select case 1:", v)
+	}
+	wg.wait()
+
+	// Regression: a select with a nil-channel recv and a nil-channel send
+	// case alongside a ready case must not dereference the nil channel; it
+	// should just never be the one chosen.
+	var nilRecv chan int
+	var nilSend chan int
+	ch = make(chan int, 1)
+	ch <- 42
+	select {
+	case v := <-nilRecv:
+		println("unreachable:", v)
+	case nilSend <- 1:
+		println("unreachable")
+	case v := <-ch:
+		println("nil-channel select n:", v)
+	}
+}
+
+// sendUntilClosed sends to ch until it panics because ch was closed out
+// from under it, then recovers and reports the panic value.
+func sendUntilClosed(ch chan int) {
+	defer wg.done()
+	defer func() {
+		if r := recover(); r != nil {
+			println("recovered:", r.(string))
+		}
+	}()
+	for {
+		ch <- 99
+	}
+}
+
+// selectSendUntilClosed behaves like sendUntilClosed but sends through a
+// select statement instead of a plain send. The second case, on a nil
+// channel, is never ready; it keeps the select from being lowered to a
+// plain send.
+func selectSendUntilClosed(ch chan int) {
+	defer wg.done()
+	defer func() {
+		if r := recover(); r != nil {
+			println("recovered:", r.(string))
+		}
+	}()
+	var never chan int
+	for {
+		select {
+		case ch <- 99:
+		case <-never:
+		}
+	}
 }
 
 func send(ch chan<- int) {
@@ -0,0 +1,44 @@
+package main
+
+import "reflect"
+
+func main() {
+	// Heterogeneous fan-in: two channels of different, differently sized
+	// element types in the same reflect.Select. Whichever is chosen must
+	// come back tagged with its own type, not the other case's.
+	ints := make(chan int32, 1)
+	strs := make(chan string, 1)
+	ints <- 7
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ints)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(strs)},
+	}
+	chosen, recv, ok := reflect.Select(cases)
+	println("chosen:", chosen, "ok:", ok, "kind:", int(recv.Kind()))
+	if chosen == 0 {
+		println("recv int32:", recv.Interface().(int32))
+	}
+
+	strs <- "hello"
+	// The select above already received ints' only value, so the next
+	// select only has one ready case: strs.
+	cases = []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ints)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(strs)},
+	}
+	chosen, recv, ok = reflect.Select(cases)
+	println("chosen:", chosen, "ok:", ok, "kind:", int(recv.Kind()))
+	if chosen == 1 {
+		println("recv string:", recv.Interface().(string))
+	}
+
+	// SelectSend and SelectDefault.
+	out := make(chan int, 1)
+	cases = []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: reflect.ValueOf(out), Send: reflect.ValueOf(42)},
+		{Dir: reflect.SelectDefault},
+	}
+	chosen, _, _ = reflect.Select(cases)
+	println("send chosen:", chosen, "value:", <-out)
+}